@@ -0,0 +1,286 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/influxdata/chronograf"
+)
+
+// defaultKapacitorHealthInterval is how often the background health
+// checker probes each kapacitor's /kapacitor/v1/ping endpoint when the
+// Service doesn't configure a different interval.
+const defaultKapacitorHealthInterval = 30 * time.Second
+
+// kapaHealthProbeTimeout bounds a single ping probe, independent of the
+// per-operation KapacitorTimeout used for proxied rule requests.
+const kapaHealthProbeTimeout = 5 * time.Second
+
+// kapaSourceLocks serializes active-kapacitor transitions per source.
+var kapaSourceLocks sync.Map // map[int]*sync.Mutex
+
+// kapaSourceLock returns the mutex guarding active-kapacitor transitions for
+// srcID, creating it on first use.
+func kapaSourceLock(srcID int) *sync.Mutex {
+	lock, _ := kapaSourceLocks.LoadOrStore(srcID, &sync.Mutex{})
+	return lock.(*sync.Mutex)
+}
+
+// deactivateSiblingKapacitors flips every other kapacitor registered
+// against active.SrcID from Active to inactive, so that at most one
+// kapacitor per source is ever active. The read-modify-write is
+// serialized per source via kapaSourceLock.
+func (h *Service) deactivateSiblingKapacitors(ctx context.Context, active chronograf.Server) error {
+	lock := kapaSourceLock(active.SrcID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	all, err := h.ServersStore.All(ctx)
+	if err != nil {
+		return fmt.Errorf("error loading kapacitors: %v", err)
+	}
+
+	for _, srv := range all {
+		if srv.SrcID != active.SrcID || srv.ID == active.ID || !srv.Active {
+			continue
+		}
+		srv.Active = false
+		if err := h.ServersStore.Update(ctx, srv); err != nil {
+			return fmt.Errorf("error deactivating kapacitor %d: %v", srv.ID, err)
+		}
+	}
+	return nil
+}
+
+// kapaHealth is the last known health of a single kapacitor instance.
+type kapaHealth struct {
+	Healthy     bool      `json:"healthy"`
+	LastError   string    `json:"lastError,omitempty"`
+	LastChecked time.Time `json:"lastChecked"`
+}
+
+// KapacitorHealthChecker periodically pings every registered kapacitor's
+// /kapacitor/v1/ping endpoint and, when configured to fail over, promotes
+// the next healthy instance for a source if the active one goes
+// unreachable.
+type KapacitorHealthChecker struct {
+	Store    chronograf.ServersStore
+	Interval time.Duration
+	Failover bool
+	Logger   chronograf.Logger
+
+	mu     sync.Mutex
+	health map[int]kapaHealth
+}
+
+// NewKapacitorHealthChecker creates a checker against store. interval <= 0
+// falls back to defaultKapacitorHealthInterval.
+func NewKapacitorHealthChecker(store chronograf.ServersStore, interval time.Duration, failover bool, logger chronograf.Logger) *KapacitorHealthChecker {
+	if interval <= 0 {
+		interval = defaultKapacitorHealthInterval
+	}
+	return &KapacitorHealthChecker{
+		Store:    store,
+		Interval: interval,
+		Failover: failover,
+		Logger:   logger,
+		health:   make(map[int]kapaHealth),
+	}
+}
+
+// Run blocks, probing every registered kapacitor on Interval until ctx is
+// canceled. Callers should invoke it as `go checker.Run(ctx)`.
+func (c *KapacitorHealthChecker) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+
+	c.checkAll(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.checkAll(ctx)
+		}
+	}
+}
+
+// checkAll probes every registered kapacitor and, if Failover is enabled,
+// promotes a healthy standby for any source whose active kapacitor just
+// went unhealthy.
+func (c *KapacitorHealthChecker) checkAll(ctx context.Context) {
+	all, err := c.Store.All(ctx)
+	if err != nil {
+		return
+	}
+
+	srcIDs := make(map[int]bool)
+	for _, srv := range all {
+		srcIDs[srv.SrcID] = true
+		c.probe(ctx, srv)
+	}
+
+	if !c.Failover {
+		return
+	}
+
+	for srcID := range srcIDs {
+		c.promoteIfNeeded(ctx, srcID)
+	}
+}
+
+// probe pings a single kapacitor and records the result.
+func (c *KapacitorHealthChecker) probe(ctx context.Context, srv chronograf.Server) {
+	health := kapaHealth{LastChecked: time.Now()}
+
+	tlsConfig, err := kapaTLSConfig(srv)
+	if err != nil {
+		health.Healthy = false
+		health.LastError = err.Error()
+		c.setHealth(srv.ID, health)
+		return
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		Timeout:   kapaHealthProbeTimeout,
+	}
+
+	req, err := http.NewRequest("GET", srv.URL+"/kapacitor/v1/ping", nil)
+	if err != nil {
+		health.Healthy = false
+		health.LastError = err.Error()
+		c.setHealth(srv.ID, health)
+		return
+	}
+	req = req.WithContext(ctx)
+	if srv.Username != "" || srv.Password != "" {
+		req.SetBasicAuth(srv.Username, srv.Password)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		health.Healthy = false
+		health.LastError = err.Error()
+		c.setHealth(srv.ID, health)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		health.Healthy = false
+		health.LastError = fmt.Sprintf("ping returned status %d", resp.StatusCode)
+		c.setHealth(srv.ID, health)
+		return
+	}
+
+	health.Healthy = true
+	c.setHealth(srv.ID, health)
+}
+
+// promoteIfNeeded flips Active to the next healthy kapacitor for srcID when
+// the currently active one is unhealthy, re-reading servers under the
+// per-source lock so it can't act on a stale snapshot.
+func (c *KapacitorHealthChecker) promoteIfNeeded(ctx context.Context, srcID int) {
+	lock := kapaSourceLock(srcID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	all, err := c.Store.All(ctx)
+	if err != nil {
+		return
+	}
+
+	var srvs []chronograf.Server
+	var active chronograf.Server
+	var haveActive bool
+	for _, srv := range all {
+		if srv.SrcID != srcID {
+			continue
+		}
+		srvs = append(srvs, srv)
+		if srv.Active {
+			active = srv
+			haveActive = true
+		}
+	}
+	if !haveActive || c.Health(active.ID).Healthy {
+		return
+	}
+
+	for _, candidate := range srvs {
+		if candidate.ID == active.ID || !c.Health(candidate.ID).Healthy {
+			continue
+		}
+
+		candidate.Active = true
+		if err := c.Store.Update(ctx, candidate); err != nil {
+			continue
+		}
+
+		demoted := active
+		demoted.Active = false
+		if err := c.Store.Update(ctx, demoted); err != nil {
+			candidate.Active = false
+			c.Store.Update(ctx, candidate)
+			continue
+		}
+		return
+	}
+}
+
+// Health returns the last known health of the kapacitor with the given ID.
+func (c *KapacitorHealthChecker) Health(id int) kapaHealth {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.health[id]
+}
+
+func (c *KapacitorHealthChecker) setHealth(id int, health kapaHealth) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.health[id] = health
+}
+
+// activeKapacitorResponse is the body of GET .../kapacitors/active.
+type activeKapacitorResponse struct {
+	kapacitor
+	Health kapaHealth `json:"health"`
+}
+
+// ActiveKapacitor retrieves the currently active kapacitor for a source,
+// along with its most recent health probe result.
+func (h *Service) ActiveKapacitor(w http.ResponseWriter, r *http.Request) {
+	srcID, err := paramID("id", r)
+	if err != nil {
+		Error(w, http.StatusUnprocessableEntity, err.Error(), h.Logger)
+		return
+	}
+
+	ctx := r.Context()
+	all, err := h.ServersStore.All(ctx)
+	if err != nil {
+		Error(w, http.StatusInternalServerError, "Error loading kapacitors", h.Logger)
+		return
+	}
+
+	for _, srv := range all {
+		if srv.SrcID != srcID || !srv.Active {
+			continue
+		}
+
+		res := activeKapacitorResponse{
+			kapacitor: newKapacitor(srv),
+		}
+		if h.KapacitorHealth != nil {
+			res.Health = h.KapacitorHealth.Health(srv.ID)
+		}
+		encodeJSON(w, http.StatusOK, res, h.Logger)
+		return
+	}
+
+	notFound(w, srcID, h.Logger)
+}
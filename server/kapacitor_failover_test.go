@@ -0,0 +1,125 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/influxdata/chronograf"
+)
+
+// fakeServersStore is an in-memory chronograf.ServersStore for exercising
+// failover logic without a real backing store.
+type fakeServersStore struct {
+	srvs map[int]chronograf.Server
+
+	// failUpdateID, if non-zero, makes Update fail for that server ID.
+	failUpdateID int
+}
+
+func (f *fakeServersStore) All(ctx context.Context) ([]chronograf.Server, error) {
+	srvs := make([]chronograf.Server, 0, len(f.srvs))
+	for _, srv := range f.srvs {
+		srvs = append(srvs, srv)
+	}
+	return srvs, nil
+}
+
+func (f *fakeServersStore) Add(ctx context.Context, srv chronograf.Server) (chronograf.Server, error) {
+	f.srvs[srv.ID] = srv
+	return srv, nil
+}
+
+func (f *fakeServersStore) Delete(ctx context.Context, srv chronograf.Server) error {
+	delete(f.srvs, srv.ID)
+	return nil
+}
+
+func (f *fakeServersStore) Get(ctx context.Context, id int) (chronograf.Server, error) {
+	srv, ok := f.srvs[id]
+	if !ok {
+		return chronograf.Server{}, fmt.Errorf("server %d not found", id)
+	}
+	return srv, nil
+}
+
+func (f *fakeServersStore) Update(ctx context.Context, srv chronograf.Server) error {
+	if f.failUpdateID != 0 && srv.ID == f.failUpdateID {
+		return fmt.Errorf("update failed for server %d", srv.ID)
+	}
+	f.srvs[srv.ID] = srv
+	return nil
+}
+
+func TestDeactivateSiblingKapacitors(t *testing.T) {
+	store := &fakeServersStore{srvs: map[int]chronograf.Server{
+		1: {ID: 1, SrcID: 1, Active: true},
+		2: {ID: 2, SrcID: 1, Active: true},
+		3: {ID: 3, SrcID: 2, Active: true}, // different source, must be untouched
+	}}
+	h := &Service{ServersStore: store}
+
+	if err := h.deactivateSiblingKapacitors(context.Background(), chronograf.Server{ID: 2, SrcID: 1, Active: true}); err != nil {
+		t.Fatalf("deactivateSiblingKapacitors() error = %v", err)
+	}
+
+	if store.srvs[1].Active {
+		t.Error("expected sibling kapacitor 1 to be deactivated")
+	}
+	if !store.srvs[2].Active {
+		t.Error("did not expect the caller's own kapacitor to be touched")
+	}
+	if !store.srvs[3].Active {
+		t.Error("did not expect a kapacitor from a different source to be touched")
+	}
+}
+
+func TestKapaSourceLock_SameMutexPerSource(t *testing.T) {
+	if kapaSourceLock(100) != kapaSourceLock(100) {
+		t.Error("expected the same source ID to return the same mutex")
+	}
+	if kapaSourceLock(100) == kapaSourceLock(200) {
+		t.Error("expected different source IDs to return different mutexes")
+	}
+}
+
+func TestPromoteIfNeeded(t *testing.T) {
+	store := &fakeServersStore{srvs: map[int]chronograf.Server{
+		1: {ID: 1, SrcID: 1, Active: true},
+		2: {ID: 2, SrcID: 1, Active: false},
+	}}
+	checker := NewKapacitorHealthChecker(store, 0, true, nil)
+	checker.setHealth(1, kapaHealth{Healthy: false})
+	checker.setHealth(2, kapaHealth{Healthy: true})
+
+	checker.promoteIfNeeded(context.Background(), 1)
+
+	if store.srvs[1].Active {
+		t.Error("expected unhealthy active kapacitor to be demoted")
+	}
+	if !store.srvs[2].Active {
+		t.Error("expected healthy standby kapacitor to be promoted")
+	}
+}
+
+func TestPromoteIfNeeded_RollsBackOnDemotionFailure(t *testing.T) {
+	store := &fakeServersStore{
+		srvs: map[int]chronograf.Server{
+			1: {ID: 1, SrcID: 1, Active: true},
+			2: {ID: 2, SrcID: 1, Active: false},
+		},
+		failUpdateID: 1, // demoting the old active kapacitor fails
+	}
+	checker := NewKapacitorHealthChecker(store, 0, true, nil)
+	checker.setHealth(1, kapaHealth{Healthy: false})
+	checker.setHealth(2, kapaHealth{Healthy: true})
+
+	checker.promoteIfNeeded(context.Background(), 1)
+
+	if store.srvs[2].Active {
+		t.Error("expected promotion of kapacitor 2 to be rolled back when demoting kapacitor 1 failed")
+	}
+	if !store.srvs[1].Active {
+		t.Error("expected kapacitor 1 to remain active since its demotion failed")
+	}
+}
@@ -0,0 +1,220 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/influxdata/chronograf"
+	kapa "github.com/influxdata/chronograf/kapacitor"
+)
+
+// kapaBatchConcurrency bounds how many rule operations within a single
+// batch request are sent to kapacitor at once.
+const kapaBatchConcurrency = 8
+
+// batchRuleOp is a single create/update/delete within a
+// KapacitorRulesBatch request.
+type batchRuleOp struct {
+	Op   string                `json:"op"` // One of "create", "update", "delete"
+	ID   string                `json:"id,omitempty"`
+	Rule *chronograf.AlertRule `json:"rule,omitempty"`
+}
+
+// batchRuleResult reports the outcome of a single batchRuleOp or
+// batchStatusOp.
+type batchRuleResult struct {
+	ID     string `json:"id"`
+	Status int    `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// batchResponse is the envelope returned by the batch rule and status
+// endpoints.
+type batchResponse struct {
+	Results []batchRuleResult `json:"results"`
+}
+
+// KapacitorRulesBatch applies a batch of rule creates/updates/deletes
+// against a single kapacitor with bounded concurrency, returning a
+// per-item result envelope with 207 Multi-Status.
+func (h *Service) KapacitorRulesBatch(w http.ResponseWriter, r *http.Request) {
+	id, err := paramID("kid", r)
+	if err != nil {
+		Error(w, http.StatusUnprocessableEntity, err.Error(), h.Logger)
+		return
+	}
+
+	srcID, err := paramID("id", r)
+	if err != nil {
+		Error(w, http.StatusUnprocessableEntity, err.Error(), h.Logger)
+		return
+	}
+
+	ctx := r.Context()
+	srv, err := h.ServersStore.Get(ctx, id)
+	if err != nil || srv.SrcID != srcID {
+		notFound(w, id, h.Logger)
+		return
+	}
+
+	var ops []batchRuleOp
+	if err := json.NewDecoder(r.Body).Decode(&ops); err != nil {
+		invalidJSON(w, h.Logger)
+		return
+	}
+
+	c, err := newKapaClient(srv)
+	if err != nil {
+		Error(w, http.StatusInternalServerError, err.Error(), h.Logger)
+		return
+	}
+
+	results := make([]batchRuleResult, len(ops))
+	sem := make(chan struct{}, kapaBatchConcurrency)
+	var wg sync.WaitGroup
+	for i, op := range ops {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, op batchRuleOp) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			opCtx, cancel := withKapaTimeout(ctx, h, srv)
+			defer cancel()
+			results[i] = applyBatchRuleOp(opCtx, c, op)
+		}(i, op)
+	}
+	wg.Wait()
+
+	encodeJSON(w, http.StatusMultiStatus, batchResponse{Results: results}, h.Logger)
+}
+
+// applyBatchRuleOp performs a single create/update/delete against c.
+func applyBatchRuleOp(ctx context.Context, c *kapa.Client, op batchRuleOp) batchRuleResult {
+	switch op.Op {
+	case "create":
+		if op.Rule == nil {
+			return batchRuleResult{ID: op.ID, Error: "rule is required for create"}
+		}
+		if err := ValidRuleRequest(*op.Rule); err != nil {
+			return batchRuleResult{ID: op.ID, Error: err.Error()}
+		}
+		task, err := c.Create(ctx, *op.Rule)
+		if err != nil {
+			return batchRuleResult{ID: op.ID, Error: err.Error()}
+		}
+		return batchRuleResult{ID: task.Rule.ID, Status: http.StatusCreated}
+
+	case "update":
+		if op.ID == "" || op.Rule == nil {
+			return batchRuleResult{ID: op.ID, Error: "id and rule are required for update"}
+		}
+		if err := ValidRuleRequest(*op.Rule); err != nil {
+			return batchRuleResult{ID: op.ID, Error: err.Error()}
+		}
+		if _, err := c.Get(ctx, op.ID); err != nil {
+			return batchRuleResult{ID: op.ID, Error: err.Error()}
+		}
+		op.Rule.ID = op.ID
+		if _, err := c.Update(ctx, c.Href(op.ID), *op.Rule); err != nil {
+			return batchRuleResult{ID: op.ID, Error: err.Error()}
+		}
+		return batchRuleResult{ID: op.ID, Status: http.StatusOK}
+
+	case "delete":
+		if op.ID == "" {
+			return batchRuleResult{ID: op.ID, Error: "id is required for delete"}
+		}
+		if _, err := c.Get(ctx, op.ID); err != nil {
+			return batchRuleResult{ID: op.ID, Error: err.Error()}
+		}
+		if err := c.Delete(ctx, c.Href(op.ID)); err != nil {
+			return batchRuleResult{ID: op.ID, Error: err.Error()}
+		}
+		return batchRuleResult{ID: op.ID, Status: http.StatusNoContent}
+
+	default:
+		return batchRuleResult{ID: op.ID, Error: fmt.Sprintf("invalid op %q", op.Op)}
+	}
+}
+
+// batchStatusOp flips a single task enabled/disabled within a
+// KapacitorRulesStatusBatch request.
+type batchStatusOp struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+// KapacitorRulesStatusBatch enables or disables many tasks in one call
+// with bounded concurrency.
+func (h *Service) KapacitorRulesStatusBatch(w http.ResponseWriter, r *http.Request) {
+	id, err := paramID("kid", r)
+	if err != nil {
+		Error(w, http.StatusUnprocessableEntity, err.Error(), h.Logger)
+		return
+	}
+
+	srcID, err := paramID("id", r)
+	if err != nil {
+		Error(w, http.StatusUnprocessableEntity, err.Error(), h.Logger)
+		return
+	}
+
+	ctx := r.Context()
+	srv, err := h.ServersStore.Get(ctx, id)
+	if err != nil || srv.SrcID != srcID {
+		notFound(w, id, h.Logger)
+		return
+	}
+
+	var ops []batchStatusOp
+	if err := json.NewDecoder(r.Body).Decode(&ops); err != nil {
+		invalidJSON(w, h.Logger)
+		return
+	}
+
+	c, err := newKapaClient(srv)
+	if err != nil {
+		Error(w, http.StatusInternalServerError, err.Error(), h.Logger)
+		return
+	}
+
+	results := make([]batchRuleResult, len(ops))
+	sem := make(chan struct{}, kapaBatchConcurrency)
+	var wg sync.WaitGroup
+	for i, op := range ops {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, op batchStatusOp) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			opCtx, cancel := withKapaTimeout(ctx, h, srv)
+			defer cancel()
+			results[i] = applyBatchStatusOp(opCtx, c, op)
+		}(i, op)
+	}
+	wg.Wait()
+
+	encodeJSON(w, http.StatusMultiStatus, batchResponse{Results: results}, h.Logger)
+}
+
+// applyBatchStatusOp enables or disables a single task against c.
+func applyBatchStatusOp(ctx context.Context, c *kapa.Client, op batchStatusOp) batchRuleResult {
+	status := KapacitorStatus{Status: op.Status}
+	if err := status.Valid(); err != nil {
+		return batchRuleResult{ID: op.ID, Error: err.Error()}
+	}
+
+	var err error
+	if op.Status == "enabled" {
+		_, err = c.Enable(ctx, c.Href(op.ID))
+	} else {
+		_, err = c.Disable(ctx, c.Href(op.ID))
+	}
+	if err != nil {
+		return batchRuleResult{ID: op.ID, Error: err.Error()}
+	}
+	return batchRuleResult{ID: op.ID, Status: http.StatusOK}
+}
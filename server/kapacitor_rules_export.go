@@ -0,0 +1,265 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/influxdata/chronograf"
+	kapa "github.com/influxdata/chronograf/kapacitor"
+)
+
+// kapaRulesBundleVersion is stamped onto every exported bundle so future
+// versions of chronograf can tell which shape they're reading.
+const kapaRulesBundleVersion = 1
+
+// exportedRule is a single rule within a rulesBundle, carrying everything
+// needed to recreate it on another kapacitor.
+type exportedRule struct {
+	Rule       chronograf.AlertRule `json:"rule"`
+	TICKScript string               `json:"tickscript"`
+	Status     string               `json:"status"`
+}
+
+// rulesBundle is the portable, version-stamped JSON document produced by
+// RulesExport and consumed by RulesImport.
+type rulesBundle struct {
+	Version int            `json:"version"`
+	Rules   []exportedRule `json:"rules"`
+}
+
+// RulesExport emits every rule known to a kapacitor as a portable bundle,
+// suitable for re-importing via RulesImport on another environment.
+func (h *Service) RulesExport(w http.ResponseWriter, r *http.Request) {
+	id, err := paramID("kid", r)
+	if err != nil {
+		Error(w, http.StatusUnprocessableEntity, err.Error(), h.Logger)
+		return
+	}
+
+	srcID, err := paramID("id", r)
+	if err != nil {
+		Error(w, http.StatusUnprocessableEntity, err.Error(), h.Logger)
+		return
+	}
+
+	ctx := r.Context()
+	srv, err := h.ServersStore.Get(ctx, id)
+	if err != nil || srv.SrcID != srcID {
+		notFound(w, id, h.Logger)
+		return
+	}
+
+	c, err := newKapaClient(srv)
+	if err != nil {
+		Error(w, http.StatusInternalServerError, err.Error(), h.Logger)
+		return
+	}
+
+	allCtx, cancel := withKapaTimeout(ctx, h, srv)
+	rules, err := c.All(allCtx)
+	cancel()
+	if err != nil {
+		if isKapaTimeout(err) {
+			kapaTimeoutError(w, h.Logger)
+			return
+		}
+		Error(w, http.StatusInternalServerError, err.Error(), h.Logger)
+		return
+	}
+
+	statusCtx, cancel := withKapaTimeout(ctx, h, srv)
+	statuses, err := c.AllStatus(statusCtx)
+	cancel()
+	if err != nil {
+		if isKapaTimeout(err) {
+			kapaTimeoutError(w, h.Logger)
+			return
+		}
+		Error(w, http.StatusInternalServerError, err.Error(), h.Logger)
+		return
+	}
+
+	bundle := rulesBundle{Version: kapaRulesBundleVersion}
+	for _, rule := range rules {
+		status, ok := statuses[rule.ID]
+		if !ok {
+			continue
+		}
+		bundle.Rules = append(bundle.Rules, exportedRule{
+			Rule:       rule,
+			TICKScript: string(rule.TICKScript),
+			Status:     status,
+		})
+	}
+
+	encodeJSON(w, http.StatusOK, bundle, h.Logger)
+}
+
+// RulesImport applies a rulesBundle against a kapacitor, creating rules
+// that don't yet exist and replacing ones that do. By default the import
+// is transactional: the first failure rolls back every rule applied so
+// far in this call. Pass ?mode=best-effort to instead apply everything
+// possible and report per-rule failures.
+func (h *Service) RulesImport(w http.ResponseWriter, r *http.Request) {
+	id, err := paramID("kid", r)
+	if err != nil {
+		Error(w, http.StatusUnprocessableEntity, err.Error(), h.Logger)
+		return
+	}
+
+	srcID, err := paramID("id", r)
+	if err != nil {
+		Error(w, http.StatusUnprocessableEntity, err.Error(), h.Logger)
+		return
+	}
+
+	ctx := r.Context()
+	srv, err := h.ServersStore.Get(ctx, id)
+	if err != nil || srv.SrcID != srcID {
+		notFound(w, id, h.Logger)
+		return
+	}
+
+	var bundle rulesBundle
+	if err := json.NewDecoder(r.Body).Decode(&bundle); err != nil {
+		invalidJSON(w, h.Logger)
+		return
+	}
+	for _, er := range bundle.Rules {
+		if err := ValidRuleRequest(er.Rule); err != nil {
+			invalidData(w, err, h.Logger)
+			return
+		}
+	}
+
+	bestEffort := r.URL.Query().Get("mode") == "best-effort"
+
+	c, err := newKapaClient(srv)
+	if err != nil {
+		Error(w, http.StatusInternalServerError, err.Error(), h.Logger)
+		return
+	}
+
+	results := make([]batchRuleResult, 0, len(bundle.Rules))
+	var applied []appliedImport
+	for _, er := range bundle.Rules {
+		res, rec, err := importRule(ctx, h, srv, c, er)
+		if err != nil {
+			if !bestEffort {
+				if rec.ID != "" {
+					applied = append(applied, rec)
+				}
+				rollbackImport(h, srv, c, applied)
+				Error(w, http.StatusInternalServerError, fmt.Sprintf("import failed on rule %s, rolled back: %v", er.Rule.ID, err), h.Logger)
+				return
+			}
+			results = append(results, batchRuleResult{ID: er.Rule.ID, Error: err.Error()})
+			continue
+		}
+		applied = append(applied, rec)
+		results = append(results, res)
+	}
+
+	encodeJSON(w, http.StatusMultiStatus, batchResponse{Results: results}, h.Logger)
+}
+
+// appliedImport records what importRule did for a single rule, so
+// rollbackImport can undo it precisely.
+type appliedImport struct {
+	ID      string
+	Created bool
+	Prior   exportedRule
+}
+
+// importRule creates or replaces a single rule from a bundle, depending on
+// whether it already exists on the kapacitor. Each call to c gets its own
+// timeout rather than sharing one deadline across the whole import. When
+// replacing an existing rule, it first captures the rule's prior state so
+// a caller can undo the update later via rollbackImport.
+func importRule(ctx context.Context, h *Service, srv chronograf.Server, c *kapa.Client, er exportedRule) (batchRuleResult, appliedImport, error) {
+	rule := er.Rule
+
+	getCtx, cancel := withKapaTimeout(ctx, h, srv)
+	current, err := c.Get(getCtx, rule.ID)
+	cancel()
+	if err == chronograf.ErrAlertNotFound {
+		createCtx, cancel := withKapaTimeout(ctx, h, srv)
+		defer cancel()
+		task, err := c.Create(createCtx, rule)
+		if err != nil {
+			return batchRuleResult{}, appliedImport{}, err
+		}
+		return batchRuleResult{ID: task.Rule.ID, Status: http.StatusCreated}, appliedImport{ID: task.Rule.ID, Created: true}, nil
+	} else if err != nil {
+		return batchRuleResult{}, appliedImport{}, err
+	}
+
+	statusCtx, cancel := withKapaTimeout(ctx, h, srv)
+	priorStatus, err := c.Status(statusCtx, c.Href(current.ID))
+	cancel()
+	if err != nil {
+		return batchRuleResult{}, appliedImport{}, err
+	}
+	rec := appliedImport{
+		ID: current.ID,
+		Prior: exportedRule{
+			Rule:       current,
+			TICKScript: string(current.TICKScript),
+			Status:     priorStatus,
+		},
+	}
+
+	updateCtx, cancel := withKapaTimeout(ctx, h, srv)
+	task, err := c.Update(updateCtx, c.Href(rule.ID), rule)
+	cancel()
+	if err != nil {
+		return batchRuleResult{}, appliedImport{}, err
+	}
+
+	if er.Status != "" {
+		statusCtx, cancel := withKapaTimeout(ctx, h, srv)
+		defer cancel()
+		var statusErr error
+		if er.Status == "enabled" {
+			_, statusErr = c.Enable(statusCtx, c.Href(rule.ID))
+		} else {
+			_, statusErr = c.Disable(statusCtx, c.Href(rule.ID))
+		}
+		if statusErr != nil {
+			// The update already landed, so hand back rec for rollback.
+			return batchRuleResult{}, rec, statusErr
+		}
+	}
+
+	return batchRuleResult{ID: task.Rule.ID, Status: http.StatusOK}, rec, nil
+}
+
+// rollbackImport best-effort undoes every rule applied so far in a failed
+// transactional import, deleting newly-created rules and restoring updated
+// ones. It runs under its own fresh timeout rather than the expired
+// deadline of the call that triggered it, and swallows its own errors.
+func rollbackImport(h *Service, srv chronograf.Server, c *kapa.Client, applied []appliedImport) {
+	for _, a := range applied {
+		ctx, cancel := withKapaTimeout(context.Background(), h, srv)
+
+		if a.Created {
+			c.Delete(ctx, c.Href(a.ID))
+			cancel()
+			continue
+		}
+
+		if _, err := c.Update(ctx, c.Href(a.ID), a.Prior.Rule); err != nil {
+			cancel()
+			continue
+		}
+		switch a.Prior.Status {
+		case "enabled":
+			c.Enable(ctx, c.Href(a.ID))
+		case "disabled":
+			c.Disable(ctx, c.Href(a.ID))
+		}
+		cancel()
+	}
+}
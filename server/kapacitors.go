@@ -1,22 +1,95 @@
 package server
 
 import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/bouk/httprouter"
 	"github.com/influxdata/chronograf"
 	kapa "github.com/influxdata/chronograf/kapacitor"
 )
 
+// defaultKapacitorTimeout bounds how long a single proxied call to
+// kapacitor may run when neither the Service nor the kapacitor itself
+// specify an override.
+const defaultKapacitorTimeout = 15 * time.Second
+
+// kapaTimeout resolves the deadline to use for a single outbound call to
+// srv: a per-kapacitor override takes precedence over the Service-wide
+// Service.KapacitorTimeout, which in turn falls back to
+// defaultKapacitorTimeout.
+func kapaTimeout(h *Service, srv chronograf.Server) time.Duration {
+	if srv.KapacitorTimeout > 0 {
+		return srv.KapacitorTimeout
+	}
+	if h.KapacitorTimeout > 0 {
+		return h.KapacitorTimeout
+	}
+	return defaultKapacitorTimeout
+}
+
+// withKapaTimeout returns a context bounded by the effective timeout for
+// srv. Callers must invoke the returned cancel func once the outbound
+// request to kapacitor completes.
+func withKapaTimeout(ctx context.Context, h *Service, srv chronograf.Server) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, kapaTimeout(h, srv))
+}
+
+// kapaTimeoutError writes a structured 504 when a proxied call to
+// kapacitor exceeds its deadline, rather than surfacing it as a generic
+// 500.
+func kapaTimeoutError(w http.ResponseWriter, logger chronograf.Logger) {
+	Error(w, http.StatusGatewayTimeout, "Timed out waiting for kapacitor to respond", logger)
+}
+
+// isKapaTimeout reports whether err represents the outbound call to
+// kapacitor exceeding its deadline. A real HTTP round-trip wraps the
+// sentinel (typically in a *url.Error), so this must unwrap rather than
+// compare directly.
+func isKapaTimeout(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded)
+}
+
+// kapaTimeoutFromSeconds converts an optional, user-supplied timeout in
+// seconds into the time.Duration stored on chronograf.Server. A nil or
+// zero value means "use the Service default".
+func kapaTimeoutFromSeconds(seconds *int) time.Duration {
+	if seconds == nil || *seconds == 0 {
+		return 0
+	}
+	return time.Duration(*seconds) * time.Second
+}
+
+// kapaTLSAuthType enumerates the effective TLS posture of a kapacitor
+// connection, derived from which of InsecureSkipVerify/CACert/Cert/Key are
+// set on the stored chronograf.Server.
+const (
+	kapaTLSAuthNone   = "none"
+	kapaTLSAuthServer = "server_only"
+	kapaTLSAuthMutual = "mutual"
+)
+
 type postKapacitorRequest struct {
-	Name     *string `json:"name"`               // User facing name of kapacitor instance.; Required: true
-	URL      *string `json:"url"`                // URL for the kapacitor backend (e.g. http://localhost:9092);/ Required: true
-	Username string  `json:"username,omitempty"` // Username for authentication to kapacitor
-	Password string  `json:"password,omitempty"`
-	Active   bool    `json:"active"`
+	Name               *string `json:"name"`               // User facing name of kapacitor instance.; Required: true
+	URL                *string `json:"url"`                // URL for the kapacitor backend (e.g. http://localhost:9092);/ Required: true
+	Username           string  `json:"username,omitempty"` // Username for authentication to kapacitor
+	Password           string  `json:"password,omitempty"`
+	InsecureSkipVerify bool    `json:"insecureSkipVerify,omitempty"` // InsecureSkipVerify as true disables TLS certificate verification
+	CACert             string  `json:"caCert,omitempty"`             // CACert is the PEM-encoded CA bundle used to verify the kapacitor's certificate
+	Cert               string  `json:"cert,omitempty"`               // Cert is the PEM-encoded client certificate used for mutual TLS
+	Key                string  `json:"key,omitempty"`                // Key is the PEM-encoded private key paired with Cert
+	TimeoutSeconds     *int    `json:"timeoutSeconds,omitempty"`     // TimeoutSeconds overrides the default per-operation kapacitor deadline
+	Active             bool    `json:"active"`
 }
 
 func (p *postKapacitorRequest) Valid() error {
@@ -32,9 +105,94 @@ func (p *postKapacitorRequest) Valid() error {
 		return fmt.Errorf("Invalid URL; no URL scheme defined")
 	}
 
+	if err := validKapaTLS(p.CACert, p.Cert, p.Key); err != nil {
+		return err
+	}
+
+	if p.TimeoutSeconds != nil && *p.TimeoutSeconds < 0 {
+		return fmt.Errorf("timeoutSeconds must not be negative")
+	}
+
+	return nil
+}
+
+// validKapaTLS ensures a CA bundle, if provided, parses as PEM-encoded
+// certificates, and that a client cert/key, if either is provided, are both
+// present and form a valid x509 key pair.
+func validKapaTLS(caCert, cert, key string) error {
+	if caCert != "" {
+		pool := x509.NewCertPool()
+		if ok := pool.AppendCertsFromPEM([]byte(caCert)); !ok {
+			return fmt.Errorf("invalid caCert: unable to parse PEM-encoded certificate")
+		}
+	}
+
+	if (cert == "") != (key == "") {
+		return fmt.Errorf("cert and key must both be provided for mutual TLS")
+	}
+
+	if cert != "" && key != "" {
+		if _, err := tls.X509KeyPair([]byte(cert), []byte(key)); err != nil {
+			return fmt.Errorf("invalid cert/key pair: %v", err)
+		}
+	}
+
 	return nil
 }
 
+// kapaTLSAuthType reports the effective TLS auth mode for a kapacitor given
+// which TLS fields are set, for display in API responses.
+func kapaTLSAuthType(srv chronograf.Server) string {
+	if srv.Cert != "" && srv.Key != "" {
+		return kapaTLSAuthMutual
+	}
+	if srv.CACert != "" || srv.InsecureSkipVerify {
+		return kapaTLSAuthServer
+	}
+	return kapaTLSAuthNone
+}
+
+// kapaTLSConfig builds the *tls.Config that should be used by the HTTP
+// transport proxying requests to this kapacitor, or nil if the connection
+// doesn't require any TLS customization.
+func kapaTLSConfig(srv chronograf.Server) (*tls.Config, error) {
+	if !srv.InsecureSkipVerify && srv.CACert == "" && srv.Cert == "" && srv.Key == "" {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{
+		InsecureSkipVerify: srv.InsecureSkipVerify,
+	}
+
+	if srv.CACert != "" {
+		pool := x509.NewCertPool()
+		if ok := pool.AppendCertsFromPEM([]byte(srv.CACert)); !ok {
+			return nil, fmt.Errorf("invalid caCert: unable to parse PEM-encoded certificate")
+		}
+		cfg.RootCAs = pool
+	}
+
+	if srv.Cert != "" && srv.Key != "" {
+		pair, err := tls.X509KeyPair([]byte(srv.Cert), []byte(srv.Key))
+		if err != nil {
+			return nil, fmt.Errorf("invalid cert/key pair: %v", err)
+		}
+		cfg.Certificates = []tls.Certificate{pair}
+	}
+
+	return cfg, nil
+}
+
+// newKapaClient constructs a kapacitor client for srv, applying any
+// TLS/mTLS configuration stored on the server.
+func newKapaClient(srv chronograf.Server) (*kapa.Client, error) {
+	tlsConfig, err := kapaTLSConfig(srv)
+	if err != nil {
+		return nil, err
+	}
+	return kapa.NewClient(srv.URL, srv.Username, srv.Password, tlsConfig), nil
+}
+
 type kapaLinks struct {
 	Proxy string `json:"proxy"` // URL location of proxy endpoint for this source
 	Self  string `json:"self"`  // Self link mapping to this resource
@@ -42,13 +200,22 @@ type kapaLinks struct {
 }
 
 type kapacitor struct {
-	ID       int       `json:"id,string"`          // Unique identifier representing a kapacitor instance.
-	Name     string    `json:"name"`               // User facing name of kapacitor instance.
-	URL      string    `json:"url"`                // URL for the kapacitor backend (e.g. http://localhost:9092)
-	Username string    `json:"username,omitempty"` // Username for authentication to kapacitor
-	Password string    `json:"password,omitempty"`
-	Active   bool      `json:"active"`
-	Links    kapaLinks `json:"links"` // Links are URI locations related to kapacitor
+	ID             int       `json:"id,string"`                // Unique identifier representing a kapacitor instance.
+	Name           string    `json:"name"`                      // User facing name of kapacitor instance.
+	URL            string    `json:"url"`                       // URL for the kapacitor backend (e.g. http://localhost:9092)
+	Username       string    `json:"username,omitempty"`        // Username for authentication to kapacitor
+	Password       string    `json:"password,omitempty"`
+	Active         bool      `json:"active"`
+	TLS            kapaTLS   `json:"tls"`                       // TLS describes the effective TLS posture of this kapacitor
+	TimeoutSeconds int       `json:"timeoutSeconds,omitempty"`  // TimeoutSeconds is the effective per-operation deadline for this kapacitor, if overridden
+	Links          kapaLinks `json:"links"`                     // Links are URI locations related to kapacitor
+}
+
+// kapaTLS is the TLS posture surfaced in the GET/POST/PATCH response. The
+// PEM-encoded CACert/Cert/Key themselves are stored opaquely and never
+// echoed back.
+type kapaTLS struct {
+	AuthType string `json:"authType"` // AuthType is one of "none", "server_only", "mutual"
 }
 
 // NewKapacitor adds valid kapacitor store store.
@@ -77,12 +244,17 @@ func (h *Service) NewKapacitor(w http.ResponseWriter, r *http.Request) {
 	}
 
 	srv := chronograf.Server{
-		SrcID:    srcID,
-		Name:     *req.Name,
-		Username: req.Username,
-		Password: req.Password,
-		URL:      *req.URL,
-		Active:   req.Active,
+		SrcID:              srcID,
+		Name:               *req.Name,
+		Username:           req.Username,
+		Password:           req.Password,
+		URL:                *req.URL,
+		Active:             req.Active,
+		InsecureSkipVerify: req.InsecureSkipVerify,
+		CACert:             req.CACert,
+		Cert:               req.Cert,
+		Key:                req.Key,
+		KapacitorTimeout:   kapaTimeoutFromSeconds(req.TimeoutSeconds),
 	}
 
 	if srv, err = h.ServersStore.Add(ctx, srv); err != nil {
@@ -91,6 +263,13 @@ func (h *Service) NewKapacitor(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if srv.Active {
+		if err := h.deactivateSiblingKapacitors(ctx, srv); err != nil {
+			unknownErrorWithMessage(w, err, h.Logger)
+			return
+		}
+	}
+
 	res := newKapacitor(srv)
 	w.Header().Add("Location", res.Links.Self)
 	encodeJSON(w, http.StatusCreated, res, h.Logger)
@@ -104,6 +283,10 @@ func newKapacitor(srv chronograf.Server) kapacitor {
 		Username: srv.Username,
 		URL:      srv.URL,
 		Active:   srv.Active,
+		TLS: kapaTLS{
+			AuthType: kapaTLSAuthType(srv),
+		},
+		TimeoutSeconds: int(srv.KapacitorTimeout / time.Second),
 		Links: kapaLinks{
 			Self:  fmt.Sprintf("%s/%d/kapacitors/%d", httpAPISrcs, srv.SrcID, srv.ID),
 			Proxy: fmt.Sprintf("%s/%d/kapacitors/%d/proxy", httpAPISrcs, srv.SrcID, srv.ID),
@@ -200,11 +383,16 @@ func (h *Service) RemoveKapacitor(w http.ResponseWriter, r *http.Request) {
 }
 
 type patchKapacitorRequest struct {
-	Name     *string `json:"name,omitempty"`     // User facing name of kapacitor instance.
-	URL      *string `json:"url,omitempty"`      // URL for the kapacitor
-	Username *string `json:"username,omitempty"` // Username for kapacitor auth
-	Password *string `json:"password,omitempty"`
-	Active   *bool   `json:"active"`
+	Name               *string `json:"name,omitempty"`     // User facing name of kapacitor instance.
+	URL                *string `json:"url,omitempty"`      // URL for the kapacitor
+	Username           *string `json:"username,omitempty"` // Username for kapacitor auth
+	Password           *string `json:"password,omitempty"`
+	InsecureSkipVerify *bool   `json:"insecureSkipVerify,omitempty"`
+	CACert             *string `json:"caCert,omitempty"`
+	Cert               *string `json:"cert,omitempty"`
+	Key                *string `json:"key,omitempty"`
+	TimeoutSeconds     *int    `json:"timeoutSeconds,omitempty"`
+	Active             *bool   `json:"active"`
 }
 
 func (p *patchKapacitorRequest) Valid() error {
@@ -217,6 +405,11 @@ func (p *patchKapacitorRequest) Valid() error {
 			return fmt.Errorf("Invalid URL; no URL scheme defined")
 		}
 	}
+
+	if p.TimeoutSeconds != nil && *p.TimeoutSeconds < 0 {
+		return fmt.Errorf("timeoutSeconds must not be negative")
+	}
+
 	return nil
 }
 
@@ -267,6 +460,26 @@ func (h *Service) UpdateKapacitor(w http.ResponseWriter, r *http.Request) {
 	if req.Active != nil {
 		srv.Active = *req.Active
 	}
+	if req.InsecureSkipVerify != nil {
+		srv.InsecureSkipVerify = *req.InsecureSkipVerify
+	}
+	if req.CACert != nil {
+		srv.CACert = *req.CACert
+	}
+	if req.Cert != nil {
+		srv.Cert = *req.Cert
+	}
+	if req.Key != nil {
+		srv.Key = *req.Key
+	}
+	if req.TimeoutSeconds != nil {
+		srv.KapacitorTimeout = kapaTimeoutFromSeconds(req.TimeoutSeconds)
+	}
+
+	if err := validKapaTLS(srv.CACert, srv.Cert, srv.Key); err != nil {
+		invalidData(w, err, h.Logger)
+		return
+	}
 
 	if err := h.ServersStore.Update(ctx, srv); err != nil {
 		msg := fmt.Sprintf("Error updating kapacitor ID %d", id)
@@ -274,6 +487,13 @@ func (h *Service) UpdateKapacitor(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if srv.Active {
+		if err := h.deactivateSiblingKapacitors(ctx, srv); err != nil {
+			unknownErrorWithMessage(w, err, h.Logger)
+			return
+		}
+	}
+
 	res := newKapacitor(srv)
 	encodeJSON(w, http.StatusOK, res, h.Logger)
 }
@@ -299,7 +519,11 @@ func (h *Service) KapacitorRulesPost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	c := kapa.NewClient(srv.URL, srv.Username, srv.Password)
+	c, err := newKapaClient(srv)
+	if err != nil {
+		Error(w, http.StatusInternalServerError, err.Error(), h.Logger)
+		return
+	}
 
 	var req chronograf.AlertRule
 	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -314,13 +538,21 @@ func (h *Service) KapacitorRulesPost(w http.ResponseWriter, r *http.Request) {
 		}
 	*/
 
-	task, err := c.Create(ctx, req)
+	kctx, cancel := withKapaTimeout(ctx, h, srv)
+	defer cancel()
+
+	task, err := c.Create(kctx, req)
 	if err != nil {
+		if isKapaTimeout(err) {
+			kapaTimeoutError(w, h.Logger)
+			return
+		}
 		Error(w, http.StatusInternalServerError, err.Error(), h.Logger)
 		return
 	}
 	res := newAlertResponse(task.Rule, task.TICKScript, task.Href, task.HrefOutput, "enabled", srv.SrcID, srv.ID)
 	w.Header().Add("Location", res.Links.Self)
+	setETag(w, res)
 	encodeJSON(w, http.StatusCreated, res, h.Logger)
 }
 
@@ -332,9 +564,10 @@ type alertLinks struct {
 
 type alertResponse struct {
 	chronograf.AlertRule
-	TICKScript string     `json:"tickscript"`
-	Status     string     `json:"status"`
-	Links      alertLinks `json:"links"`
+	TICKScript      string     `json:"tickscript"`
+	Status          string     `json:"status"`
+	ResourceVersion string     `json:"resourceVersion"` // ResourceVersion identifies this revision of the rule for optimistic concurrency control
+	Links           alertLinks `json:"links"`
 }
 
 // newAlertResponse formats task into an alertResponse
@@ -346,8 +579,9 @@ func newAlertResponse(rule chronograf.AlertRule, tickScript chronograf.TICKScrip
 			Kapacitor: fmt.Sprintf("/chronograf/v1/sources/%d/kapacitors/%d/proxy?path=%s", srcID, kapaID, url.QueryEscape(href)),
 			Output:    fmt.Sprintf("/chronograf/v1/sources/%d/kapacitors/%d/proxy?path=%s", srcID, kapaID, url.QueryEscape(hrefOutput)),
 		},
-		TICKScript: string(tickScript),
-		Status:     status,
+		TICKScript:      string(tickScript),
+		Status:          status,
+		ResourceVersion: ruleResourceVersion(rule, tickScript),
 	}
 
 	if res.Alerts == nil {
@@ -398,6 +632,43 @@ func newAlertResponse(rule chronograf.AlertRule, tickScript chronograf.TICKScrip
 	return res
 }
 
+// ruleResourceVersion derives a stable identifier for a specific revision
+// of an alert rule from a hash of its JSON-marshaled definition and
+// TICKscript, surfaced as both the resourceVersion field and the ETag
+// header so clients can make conditional requests with If-Match.
+func ruleResourceVersion(rule chronograf.AlertRule, tickScript chronograf.TICKScript) string {
+	h := sha256.New()
+	if b, err := json.Marshal(rule); err == nil {
+		h.Write(b)
+	}
+	h.Write([]byte(tickScript))
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// setETag writes the resource version of res as a quoted HTTP ETag header.
+func setETag(w http.ResponseWriter, res alertResponse) {
+	w.Header().Set("ETag", fmt.Sprintf("%q", res.ResourceVersion))
+}
+
+// checkIfMatch compares the If-Match header, if present, against current's
+// resource version. It writes a 409 Conflict and returns false when the
+// client's version is stale; callers should abort on a false return. If no
+// If-Match header is present, the request is treated as unconditional.
+func checkIfMatch(w http.ResponseWriter, r *http.Request, current chronograf.AlertRule, tickScript chronograf.TICKScript, logger chronograf.Logger) bool {
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		return true
+	}
+	if unquoted, err := strconv.Unquote(ifMatch); err == nil {
+		ifMatch = unquoted
+	}
+	if ifMatch != ruleResourceVersion(current, tickScript) {
+		Error(w, http.StatusConflict, "Rule has been modified since it was last fetched", logger)
+		return false
+	}
+	return true
+}
+
 // ValidRuleRequest checks if the requested rule change is valid
 func ValidRuleRequest(rule chronograf.AlertRule) error {
 	if rule.Query == nil {
@@ -440,7 +711,11 @@ func (h *Service) KapacitorRulesPut(w http.ResponseWriter, r *http.Request) {
 	}
 
 	tid := httprouter.GetParamFromContext(ctx, "tid")
-	c := kapa.NewClient(srv.URL, srv.Username, srv.Password)
+	c, err := newKapaClient(srv)
+	if err != nil {
+		Error(w, http.StatusInternalServerError, err.Error(), h.Logger)
+		return
+	}
 	var req chronograf.AlertRule
 	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
 		invalidJSON(w, h.Logger)
@@ -454,24 +729,41 @@ func (h *Service) KapacitorRulesPut(w http.ResponseWriter, r *http.Request) {
 		}
 	*/
 
+	kctx, cancel := withKapaTimeout(ctx, h, srv)
+	defer cancel()
+
 	// Check if the rule exists and is scoped correctly
-	if _, err = c.Get(ctx, tid); err != nil {
+	current, err := c.Get(kctx, tid)
+	if err != nil {
 		if err == chronograf.ErrAlertNotFound {
 			notFound(w, id, h.Logger)
 			return
 		}
+		if isKapaTimeout(err) {
+			kapaTimeoutError(w, h.Logger)
+			return
+		}
 		Error(w, http.StatusInternalServerError, err.Error(), h.Logger)
 		return
 	}
 
+	if !checkIfMatch(w, r, current, current.TICKScript, h.Logger) {
+		return
+	}
+
 	// Replace alert completely with this new alert.
 	req.ID = tid
-	task, err := c.Update(ctx, c.Href(tid), req)
+	task, err := c.Update(kctx, c.Href(tid), req)
 	if err != nil {
+		if isKapaTimeout(err) {
+			kapaTimeoutError(w, h.Logger)
+			return
+		}
 		Error(w, http.StatusInternalServerError, err.Error(), h.Logger)
 		return
 	}
 	res := newAlertResponse(task.Rule, task.TICKScript, task.Href, task.HrefOutput, "enabled", srv.SrcID, srv.ID)
+	setETag(w, res)
 	encodeJSON(w, http.StatusOK, res, h.Logger)
 }
 
@@ -510,7 +802,11 @@ func (h *Service) KapacitorRulesStatus(w http.ResponseWriter, r *http.Request) {
 	}
 
 	tid := httprouter.GetParamFromContext(ctx, "tid")
-	c := kapa.NewClient(srv.URL, srv.Username, srv.Password)
+	c, err := newKapaClient(srv)
+	if err != nil {
+		Error(w, http.StatusInternalServerError, err.Error(), h.Logger)
+		return
+	}
 
 	var req KapacitorStatus
 	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -522,34 +818,54 @@ func (h *Service) KapacitorRulesStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	kctx, cancel := withKapaTimeout(ctx, h, srv)
+	defer cancel()
+
 	// Check if the rule exists and is scoped correctly
-	alert, err := c.Get(ctx, tid)
+	alert, err := c.Get(kctx, tid)
 	if err != nil {
 		if err == chronograf.ErrAlertNotFound {
 			notFound(w, id, h.Logger)
 			return
 		}
+		if isKapaTimeout(err) {
+			kapaTimeoutError(w, h.Logger)
+			return
+		}
 		Error(w, http.StatusInternalServerError, err.Error(), h.Logger)
 		return
 	}
 
+	if !checkIfMatch(w, r, alert, alert.TICKScript, h.Logger) {
+		return
+	}
+
 	var task *kapa.Task
 	if req.Status == "enabled" {
-		task, err = c.Enable(ctx, c.Href(tid))
+		task, err = c.Enable(kctx, c.Href(tid))
 	} else {
-		task, err = c.Disable(ctx, c.Href(tid))
+		task, err = c.Disable(kctx, c.Href(tid))
 	}
 
 	if err != nil {
+		if isKapaTimeout(err) {
+			kapaTimeoutError(w, h.Logger)
+			return
+		}
 		Error(w, http.StatusInternalServerError, err.Error(), h.Logger)
 		return
 	}
 
 	res := newAlertResponse(alert, task.TICKScript, task.Href, task.HrefOutput, req.Status, srv.SrcID, srv.ID)
+	setETag(w, res)
 	encodeJSON(w, http.StatusOK, res, h.Logger)
 }
 
-// KapacitorRulesGet retrieves all rules
+// KapacitorRulesGet retrieves all rules, aggregated across every kapacitor
+// registered for this source and de-duplicated by rule ID, querying healthy
+// instances before unhealthy ones. A kapacitor that fails to respond is
+// skipped, except the one named in the request: its failure is returned as
+// the response rather than served from the other instances.
 func (h *Service) KapacitorRulesGet(w http.ResponseWriter, r *http.Request) {
 	id, err := paramID("kid", r)
 	if err != nil {
@@ -570,32 +886,90 @@ func (h *Service) KapacitorRulesGet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	c := kapa.NewClient(srv.URL, srv.Username, srv.Password)
-	rules, err := c.All(ctx)
+	res := allAlertsResponse{
+		Rules: []alertResponse{},
+	}
+	seen := make(map[string]bool)
+	for _, ksrv := range h.sourceKapacitors(ctx, srcID) {
+		ars, err := h.rulesForKapacitor(ctx, ksrv)
+		if err != nil {
+			// An unreachable or timed-out kapacitor shouldn't take down the
+			// whole aggregate; the requested one failing entirely is reported.
+			if ksrv.ID == id {
+				if isKapaTimeout(err) {
+					kapaTimeoutError(w, h.Logger)
+					return
+				}
+				Error(w, http.StatusInternalServerError, err.Error(), h.Logger)
+				return
+			}
+			continue
+		}
+
+		for _, ar := range ars {
+			if seen[ar.ID] {
+				continue
+			}
+			seen[ar.ID] = true
+			res.Rules = append(res.Rules, ar)
+		}
+	}
+	encodeJSON(w, http.StatusOK, res, h.Logger)
+}
+
+// sourceKapacitors returns every kapacitor registered for srcID, ordered
+// with healthy instances first so that de-duplication in
+// KapacitorRulesGet prefers a healthy source of truth for each rule.
+func (h *Service) sourceKapacitors(ctx context.Context, srcID int) []chronograf.Server {
+	all, err := h.ServersStore.All(ctx)
 	if err != nil {
-		Error(w, http.StatusInternalServerError, err.Error(), h.Logger)
-		return
+		return nil
 	}
-	statuses, err := c.AllStatus(ctx)
+
+	var healthy, unhealthy []chronograf.Server
+	for _, srv := range all {
+		if srv.SrcID != srcID {
+			continue
+		}
+		if h.KapacitorHealth != nil && !h.KapacitorHealth.Health(srv.ID).Healthy {
+			unhealthy = append(unhealthy, srv)
+			continue
+		}
+		healthy = append(healthy, srv)
+	}
+	return append(healthy, unhealthy...)
+}
+
+// rulesForKapacitor fetches and formats every rule known to a single
+// kapacitor instance.
+func (h *Service) rulesForKapacitor(ctx context.Context, srv chronograf.Server) ([]alertResponse, error) {
+	c, err := newKapaClient(srv)
 	if err != nil {
-		Error(w, http.StatusInternalServerError, err.Error(), h.Logger)
-		return
+		return nil, err
 	}
 
-	res := allAlertsResponse{
-		Rules: []alertResponse{},
+	kctx, cancel := withKapaTimeout(ctx, h, srv)
+	defer cancel()
+
+	rules, err := c.All(kctx)
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := c.AllStatus(kctx)
+	if err != nil {
+		return nil, err
 	}
+
+	ars := make([]alertResponse, 0, len(rules))
 	for _, rule := range rules {
 		status, ok := statuses[rule.ID]
 		// The defined rule is not actually in kapacitor
 		if !ok {
 			continue
 		}
-
-		ar := newAlertResponse(rule, rule.TICKScript, c.Href(rule.ID), c.HrefOutput(rule.ID), status, srv.SrcID, srv.ID)
-		res.Rules = append(res.Rules, ar)
+		ars = append(ars, newAlertResponse(rule, rule.TICKScript, c.Href(rule.ID), c.HrefOutput(rule.ID), status, srv.SrcID, srv.ID))
 	}
-	encodeJSON(w, http.StatusOK, res, h.Logger)
+	return ars, nil
 }
 
 type allAlertsResponse struct {
@@ -624,25 +998,41 @@ func (h *Service) KapacitorRulesID(w http.ResponseWriter, r *http.Request) {
 	}
 	tid := httprouter.GetParamFromContext(ctx, "tid")
 
-	c := kapa.NewClient(srv.URL, srv.Username, srv.Password)
+	c, err := newKapaClient(srv)
+	if err != nil {
+		Error(w, http.StatusInternalServerError, err.Error(), h.Logger)
+		return
+	}
+
+	kctx, cancel := withKapaTimeout(ctx, h, srv)
+	defer cancel()
 
 	// Check if the rule exists within scope
-	rule, err := c.Get(ctx, tid)
+	rule, err := c.Get(kctx, tid)
 	if err != nil {
 		if err == chronograf.ErrAlertNotFound {
 			notFound(w, id, h.Logger)
 			return
 		}
+		if isKapaTimeout(err) {
+			kapaTimeoutError(w, h.Logger)
+			return
+		}
 		Error(w, http.StatusInternalServerError, err.Error(), h.Logger)
 		return
 	}
-	status, err := c.Status(ctx, c.Href(rule.ID))
+	status, err := c.Status(kctx, c.Href(rule.ID))
 	if err != nil {
+		if isKapaTimeout(err) {
+			kapaTimeoutError(w, h.Logger)
+			return
+		}
 		Error(w, http.StatusInternalServerError, err.Error(), h.Logger)
 		return
 	}
 
 	res := newAlertResponse(rule, rule.TICKScript, c.Href(rule.ID), c.HrefOutput(rule.ID), status, srv.SrcID, srv.ID)
+	setETag(w, res)
 	encodeJSON(w, http.StatusOK, res, h.Logger)
 }
 
@@ -667,19 +1057,41 @@ func (h *Service) KapacitorRulesDelete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	c := kapa.NewClient(srv.URL, srv.Username, srv.Password)
+	c, err := newKapaClient(srv)
+	if err != nil {
+		Error(w, http.StatusInternalServerError, err.Error(), h.Logger)
+		return
+	}
 
 	tid := httprouter.GetParamFromContext(ctx, "tid")
+
+	kctx, cancel := withKapaTimeout(ctx, h, srv)
+	defer cancel()
+
 	// Check if the rule is linked to this server and kapacitor
-	if _, err := c.Get(ctx, tid); err != nil {
+	current, err := c.Get(kctx, tid)
+	if err != nil {
 		if err == chronograf.ErrAlertNotFound {
 			notFound(w, id, h.Logger)
 			return
 		}
+		if isKapaTimeout(err) {
+			kapaTimeoutError(w, h.Logger)
+			return
+		}
 		Error(w, http.StatusInternalServerError, err.Error(), h.Logger)
 		return
 	}
-	if err := c.Delete(ctx, c.Href(tid)); err != nil {
+
+	if !checkIfMatch(w, r, current, current.TICKScript, h.Logger) {
+		return
+	}
+
+	if err := c.Delete(kctx, c.Href(tid)); err != nil {
+		if isKapaTimeout(err) {
+			kapaTimeoutError(w, h.Logger)
+			return
+		}
 		Error(w, http.StatusInternalServerError, err.Error(), h.Logger)
 		return
 	}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/influxdata/chronograf"
+	clog "github.com/influxdata/chronograf/log"
+)
+
+func newTestAlertRule(id, field string) chronograf.AlertRule {
+	return chronograf.AlertRule{
+		ID:   id,
+		Name: "test rule",
+		Query: &chronograf.QueryConfig{
+			ID:     id,
+			Fields: []chronograf.Field{{Value: field}},
+		},
+	}
+}
+
+func TestRuleResourceVersion_StableAcrossPointerReallocation(t *testing.T) {
+	a := newTestAlertRule("1", "usage_idle")
+	b := newTestAlertRule("1", "usage_idle")
+
+	if a.Query == b.Query {
+		t.Fatal("test setup: expected distinct Query pointers")
+	}
+
+	va := ruleResourceVersion(a, "stream\n    |from()")
+	vb := ruleResourceVersion(b, "stream\n    |from()")
+	if va != vb {
+		t.Errorf("expected equal rules with distinct Query pointers to hash the same, got %q and %q", va, vb)
+	}
+}
+
+func TestRuleResourceVersion_ChangesWithContent(t *testing.T) {
+	a := newTestAlertRule("1", "usage_idle")
+	b := newTestAlertRule("1", "usage_user")
+
+	va := ruleResourceVersion(a, "stream\n    |from()")
+	vb := ruleResourceVersion(b, "stream\n    |from()")
+	if va == vb {
+		t.Error("expected rules with different queries to hash differently")
+	}
+}
+
+func TestCheckIfMatch(t *testing.T) {
+	logger := clog.New(clog.ParseLevel("info"))
+	current := newTestAlertRule("1", "usage_idle")
+	tickScript := chronograf.TICKScript("stream\n    |from()")
+	version := ruleResourceVersion(current, tickScript)
+
+	tests := []struct {
+		name     string
+		ifMatch  string
+		wantOK   bool
+		wantCode int
+	}{
+		{name: "no If-Match is unconditional", ifMatch: "", wantOK: true},
+		{name: "matching If-Match passes", ifMatch: `"` + version + `"`, wantOK: true},
+		{name: "stale If-Match is rejected", ifMatch: `"stale"`, wantOK: false, wantCode: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("PUT", "/", nil)
+			if tt.ifMatch != "" {
+				r.Header.Set("If-Match", tt.ifMatch)
+			}
+			w := httptest.NewRecorder()
+
+			ok := checkIfMatch(w, r, current, tickScript, logger)
+			if ok != tt.wantOK {
+				t.Errorf("checkIfMatch() = %v, want %v", ok, tt.wantOK)
+			}
+			if !tt.wantOK && w.Code != tt.wantCode {
+				t.Errorf("got status %d, want %d", w.Code, tt.wantCode)
+			}
+		})
+	}
+}